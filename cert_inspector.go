@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+)
+
+// CertInspector walks the certificate chain presented during a handshake
+// and performs a separate hostname-verified dial to check whether the
+// chain validates against the system root pool.
+type CertInspector struct {
+	config Config
+}
+
+// NewCertInspector creates a new instance of CertInspector
+func NewCertInspector(cfg Config) *CertInspector {
+	return &CertInspector{config: cfg}
+}
+
+// CertDetail summarizes a single certificate in the peer's chain.
+type CertDetail struct {
+	Subject            string
+	Issuer             string
+	SANs               []string
+	KeyAlgorithm       string
+	KeySizeBits        int
+	SignatureAlgorithm string
+	NotBefore          time.Time
+	NotAfter           time.Time
+	DaysUntilExpiry    int
+	SHA256Fingerprint  string
+}
+
+// CertReport is the full result of inspecting a handshake's certificate
+// chain plus a real hostname-verified validation pass.
+type CertReport struct {
+	Chain           []CertDetail
+	OCSPStapled     bool
+	SCTPresent      bool
+	ChainValid      bool
+	ValidationError string
+}
+
+// dialFunc dials the target the same way TLSChecker does (including any
+// -starttls upgrade and rate limiting), returning an established TLS
+// connection for the given tls.Config.
+type dialFunc func(ctx context.Context, cfg *tls.Config) (*tls.Conn, error)
+
+// Inspect summarizes state (captured from the handshake already performed
+// by TLSChecker) and separately re-dials, via dial, with full hostname
+// verification to determine whether the chain validates against the
+// system root pool. dial must be TLSChecker.connect so the verification
+// pass goes through the same -starttls upgrade and rate limiter as every
+// other probe.
+func (ci *CertInspector) Inspect(ctx context.Context, state tls.ConnectionState, dial dialFunc) *CertReport {
+	report := &CertReport{
+		OCSPStapled: len(state.OCSPResponse) > 0,
+		SCTPresent:  len(state.SignedCertificateTimestamps) > 0,
+	}
+
+	for _, cert := range state.PeerCertificates {
+		report.Chain = append(report.Chain, describeCertificate(cert))
+	}
+
+	valid, err := ci.verifyHostname(ctx, dial)
+	report.ChainValid = valid
+	if err != nil {
+		report.ValidationError = err.Error()
+	}
+
+	return report
+}
+
+func describeCertificate(cert *x509.Certificate) CertDetail {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	detail := CertDetail{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SANs:               subjectAltNames(cert),
+		KeyAlgorithm:       cert.PublicKeyAlgorithm.String(),
+		KeySizeBits:        publicKeySizeBits(cert.PublicKey),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		DaysUntilExpiry:    int(time.Until(cert.NotAfter).Hours() / 24),
+		SHA256Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+
+	return detail
+}
+
+func subjectAltNames(cert *x509.Certificate) []string {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+func publicKeySizeBits(pub interface{}) int {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return len(key) * 8
+	default:
+		return 0
+	}
+}
+
+// verifyHostname performs a second dial, through dial, with full
+// certificate verification (InsecureSkipVerify: false) and a ServerName
+// derived from the target, to check whether the chain validates against
+// the system root pool rather than just completing a handshake. Routing
+// through dial (TLSChecker.connect) ensures a -starttls target gets the
+// same plaintext upgrade dance before this handshake is attempted.
+func (ci *CertInspector) verifyHostname(ctx context.Context, dial dialFunc) (bool, error) {
+	host, _, err := net.SplitHostPort(ci.config.target)
+	if err != nil {
+		host = ci.config.target
+	}
+
+	conn, err := dial(ctx, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: false,
+	})
+	if err != nil {
+		return false, fmt.Errorf("chain validation failed: %w", err)
+	}
+	defer conn.Close()
+
+	return true, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import "crypto/tls"
+
+// namedGroup is one key-exchange group probed alongside the cipher suite
+// matrix: an ECDHE curve or TLS 1.3 hybrid group.
+//
+// Go's crypto/tls client has never supported classic FFDHE (finite-field
+// Diffie-Hellman) cipher suites, so there is no CurvePreferences-style way
+// to probe FFDHE groups from this tool; only the elliptic-curve and hybrid
+// groups below are reachable.
+type namedGroup struct {
+	ID   tls.CurveID
+	Name string
+}
+
+// namedGroups is the set of groups scanNamedGroups probes. Populated here
+// with the groups supported by every Go version this tool targets;
+// namedgroups_hybrid.go's build-tagged variant appends newer ones (e.g. the
+// TLS 1.3 hybrid groups) when the toolchain exposes them.
+var namedGroups = []namedGroup{
+	{ID: tls.X25519, Name: "X25519"},
+	{ID: tls.CurveP256, Name: "P-256"},
+	{ID: tls.CurveP384, Name: "P-384"},
+	{ID: tls.CurveP521, Name: "P-521"},
+}
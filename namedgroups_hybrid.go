@@ -0,0 +1,11 @@
+//go:build go1.24
+
+package main
+
+import "crypto/tls"
+
+// init registers the TLS 1.3 hybrid post-quantum group added in Go 1.24,
+// which doesn't exist on older toolchains.
+func init() {
+	namedGroups = append(namedGroups, namedGroup{ID: tls.X25519MLKEM768, Name: "X25519MLKEM768"})
+}
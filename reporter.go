@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Reporter renders a completed TLSChecker scan to w. Implementations must
+// not mutate tc; Report may be called at most once per checker.
+type Reporter interface {
+	Report(w io.Writer, tc *TLSChecker) error
+}
+
+// reporterFor resolves the -format flag value to a Reporter.
+func reporterFor(format string) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return TextReporter{}, nil
+	case "json":
+		return JSONReporter{}, nil
+	case "sarif":
+		return SARIFReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (want text, json, or sarif)", format)
+	}
+}
+
+// TextReporter renders the human-readable terminal report: the cipher suite
+// matrix, named groups, and certificate chain.
+type TextReporter struct{}
+
+func (TextReporter) Report(w io.Writer, tc *TLSChecker) error {
+	fmt.Fprintf(w, "\nTLS Connection Information for %s:\n", tc.config.target)
+	fmt.Fprintf(w, "Negotiated Version: %s\n", getTLSVersionString(tc.tlsVersion))
+	fmt.Fprintf(w, "Handshake Latency: %s\n\n", tc.handshakeLatency)
+
+	supportedTotal := 0
+	fmt.Fprintln(w, "Cipher Suite Matrix:")
+	for _, version := range scannedVersions {
+		suiteResults, ok := tc.results[version]
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s:\n", getTLSVersionString(version))
+		for _, suite := range candidateSuites(version) {
+			supported := suiteResults[suite.ID]
+			if supported {
+				supportedTotal++
+				fmt.Fprintf(w, "  ✓ %s\n", suite.Name)
+			} else if tc.config.verbose {
+				fmt.Fprintf(w, "  ✗ %s\n", suite.Name)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\nSummary: %d supported, %d unsupported cipher suite probes\n",
+		supportedTotal, tc.errCount)
+
+	fmt.Fprintln(w, "\nNamed Groups:")
+	for _, group := range namedGroups {
+		if tc.groupResults[group.ID] {
+			fmt.Fprintf(w, "  ✓ %s\n", group.Name)
+		} else if tc.config.verbose {
+			fmt.Fprintf(w, "  ✗ %s\n", group.Name)
+		}
+	}
+
+	if tc.certReport != nil {
+		fmt.Fprintln(w, "\nCertificate Chain:")
+		for i, cert := range tc.certReport.Chain {
+			fmt.Fprintf(w, "  [%d] %s\n", i, cert.Subject)
+			fmt.Fprintf(w, "      Issuer: %s\n", cert.Issuer)
+			if len(cert.SANs) > 0 {
+				fmt.Fprintf(w, "      SANs: %s\n", strings.Join(cert.SANs, ", "))
+			}
+			fmt.Fprintf(w, "      Key: %s (%d bits), Signature: %s\n",
+				cert.KeyAlgorithm, cert.KeySizeBits, cert.SignatureAlgorithm)
+			fmt.Fprintf(w, "      Valid: %s - %s (%d days until expiry)\n",
+				cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339), cert.DaysUntilExpiry)
+			fmt.Fprintf(w, "      SHA-256: %s\n", cert.SHA256Fingerprint)
+		}
+		fmt.Fprintf(w, "  OCSP Stapled: %t, SCT Present: %t\n", tc.certReport.OCSPStapled, tc.certReport.SCTPresent)
+		if tc.certReport.ChainValid {
+			fmt.Fprintln(w, "  Hostname Validation: PASSED")
+		} else {
+			fmt.Fprintf(w, "  Hostname Validation: FAILED (%s)\n", tc.certReport.ValidationError)
+		}
+	}
+
+	return nil
+}
+
+// jsonSuiteResult is one version/suite probe in the JSON report.
+type jsonSuiteResult struct {
+	Version   string `json:"version"`
+	SuiteName string `json:"suite_name"`
+	SuiteID   string `json:"suite_id"` // IANA ID, e.g. "0x1301"
+	Supported bool   `json:"supported"`
+}
+
+// jsonGroupResult is one named-group (ECDHE curve / hybrid KEM) probe.
+type jsonGroupResult struct {
+	Name      string `json:"name"`
+	Supported bool   `json:"supported"`
+}
+
+// jsonCertSummary is a condensed view of one certificate in the chain.
+type jsonCertSummary struct {
+	Subject            string   `json:"subject"`
+	Issuer             string   `json:"issuer"`
+	SANs               []string `json:"sans,omitempty"`
+	KeyAlgorithm       string   `json:"key_algorithm"`
+	KeySizeBits        int      `json:"key_size_bits"`
+	SignatureAlgorithm string   `json:"signature_algorithm"`
+	NotAfter           string   `json:"not_after"`
+	DaysUntilExpiry    int      `json:"days_until_expiry"`
+	SHA256Fingerprint  string   `json:"sha256_fingerprint"`
+}
+
+// jsonReport is the top-level document emitted by JSONReporter.
+type jsonReport struct {
+	Target              string            `json:"target"`
+	NegotiatedVersion   string            `json:"negotiated_version"`
+	HandshakeLatencyMS  float64           `json:"handshake_latency_ms"`
+	CipherSuites        []jsonSuiteResult `json:"cipher_suites"`
+	NamedGroups         []jsonGroupResult `json:"named_groups"`
+	CertificateChain    []jsonCertSummary `json:"certificate_chain"`
+	OCSPStapled         bool              `json:"ocsp_stapled"`
+	SCTPresent          bool              `json:"sct_present"`
+	ChainValid          bool              `json:"chain_valid"`
+	ChainValidationErr  string            `json:"chain_validation_error,omitempty"`
+	SupportedSuiteCount int               `json:"supported_suite_count"`
+}
+
+// JSONReporter emits a machine-readable report suitable for CI pipelines.
+type JSONReporter struct{}
+
+func (JSONReporter) Report(w io.Writer, tc *TLSChecker) error {
+	report := buildJSONReport(tc)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// buildJSONReport assembles the full JSON report document for tc. It is
+// shared by JSONReporter and Scanner, which aggregates one of these per
+// target into a single batch document.
+func buildJSONReport(tc *TLSChecker) jsonReport {
+	report := jsonReport{
+		Target:             tc.config.target,
+		NegotiatedVersion:  getTLSVersionString(tc.tlsVersion),
+		HandshakeLatencyMS: float64(tc.handshakeLatency.Microseconds()) / 1000.0,
+	}
+
+	for _, version := range scannedVersions {
+		suiteResults, ok := tc.results[version]
+		if !ok {
+			continue
+		}
+		for _, suite := range candidateSuites(version) {
+			supported := suiteResults[suite.ID]
+			if supported {
+				report.SupportedSuiteCount++
+			}
+			report.CipherSuites = append(report.CipherSuites, jsonSuiteResult{
+				Version:   getTLSVersionString(version),
+				SuiteName: suite.Name,
+				SuiteID:   fmt.Sprintf("0x%04X", suite.ID),
+				Supported: supported,
+			})
+		}
+	}
+
+	for _, group := range namedGroups {
+		report.NamedGroups = append(report.NamedGroups, jsonGroupResult{
+			Name:      group.Name,
+			Supported: tc.groupResults[group.ID],
+		})
+	}
+
+	if tc.certReport != nil {
+		report.OCSPStapled = tc.certReport.OCSPStapled
+		report.SCTPresent = tc.certReport.SCTPresent
+		report.ChainValid = tc.certReport.ChainValid
+		report.ChainValidationErr = tc.certReport.ValidationError
+
+		for _, cert := range tc.certReport.Chain {
+			report.CertificateChain = append(report.CertificateChain, jsonCertSummary{
+				Subject:            cert.Subject,
+				Issuer:             cert.Issuer,
+				SANs:               cert.SANs,
+				KeyAlgorithm:       cert.KeyAlgorithm,
+				KeySizeBits:        cert.KeySizeBits,
+				SignatureAlgorithm: cert.SignatureAlgorithm,
+				NotAfter:           cert.NotAfter.Format(time.RFC3339),
+				DaysUntilExpiry:    cert.DaysUntilExpiry,
+				SHA256Fingerprint:  cert.SHA256Fingerprint,
+			})
+		}
+	}
+
+	return report
+}
+
+// weakSuitePatterns flags cipher suite names considered insecure enough to
+// report as a SARIF finding: RC4, 3DES, export-grade, and NULL. isWeakSuite
+// also flags CBC-SHA1 suites by their name suffix.
+var weakSuitePatterns = []string{"RC4", "3DES", "DES_CBC", "EXPORT", "NULL"}
+
+func isWeakSuite(name string) bool {
+	for _, pattern := range weakSuitePatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	// CBC-SHA1 (as opposed to the stronger CBC_SHA256/CBC_SHA384 suites).
+	return strings.HasSuffix(name, "_CBC_SHA")
+}
+
+// sarifLog mirrors the minimal subset of the SARIF 2.1.0 schema needed to
+// upload weak/insecure cipher suite findings to code-scanning dashboards.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFReporter emits findings for weak/insecure cipher suites so scan
+// results can be uploaded to code-scanning dashboards.
+type SARIFReporter struct{}
+
+func (SARIFReporter) Report(w io.Writer, tc *TLSChecker) error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:    "tls-check",
+						Version: "1.0.0",
+					},
+				},
+			},
+		},
+	}
+
+	for _, version := range scannedVersions {
+		suiteResults, ok := tc.results[version]
+		if !ok {
+			continue
+		}
+		for _, suite := range candidateSuites(version) {
+			if !suiteResults[suite.ID] || !isWeakSuite(suite.Name) {
+				continue
+			}
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+				RuleID: "weak-cipher-suite",
+				Level:  "error",
+				Message: sarifMessage{
+					Text: fmt.Sprintf("%s supports weak cipher suite %s under %s",
+						tc.config.target, suite.Name, getTLSVersionString(version)),
+				},
+				Locations: []sarifLocation{
+					{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: tc.config.target},
+						},
+					},
+				},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple token-bucket limiter shared across every target
+// a Scanner is running, capping the global rate of new TLS connections
+// independent of each TLSChecker's own per-host suite-probe semaphore.
+type rateLimiter struct {
+	tokens chan struct{}
+	ticker *time.Ticker
+}
+
+// newRateLimiter creates a limiter that releases one token every 1/rps
+// seconds. rps <= 0 disables rate limiting entirely.
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(float64(time.Second) / rps)
+	if interval <= 0 {
+		// rps large enough that 1/rps underflows to zero (or goes negative
+		// via float rounding): time.NewTicker panics on a non-positive
+		// duration, so floor at 1ns rather than crash on an -rps value the
+		// flag happily accepts.
+		interval = 1
+	}
+
+	rl := &rateLimiter{
+		tokens: make(chan struct{}, 1),
+		ticker: time.NewTicker(interval),
+	}
+
+	go func() {
+		for range rl.ticker.C {
+			select {
+			case rl.tokens <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return rl
+}
+
+// wait blocks until a token is available or ctx is canceled. A nil limiter
+// never blocks.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+	select {
+	case <-rl.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Scanner runs a TLSChecker against each of a set of targets, bounding
+// target-level concurrency with a worker pool that is separate from the
+// suite-level concurrency each TLSChecker manages internally.
+type Scanner struct {
+	targets     []string
+	cfgTemplate Config
+	workers     int
+	limiter     *rateLimiter
+}
+
+// NewScanner creates a Scanner for targets, cloning cfgTemplate (minus
+// target) into one TLSChecker per target. workers bounds how many targets
+// are scanned concurrently; rps bounds the global connection rate across
+// all of them.
+func NewScanner(targets []string, cfgTemplate Config, workers int, rps float64) *Scanner {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Scanner{
+		targets:     targets,
+		cfgTemplate: cfgTemplate,
+		workers:     workers,
+		limiter:     newRateLimiter(rps),
+	}
+}
+
+// scanOutcome is one target's result in the aggregated batch document.
+type scanOutcome struct {
+	Report *jsonReport `json:"report,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Run scans every target, bounded by the worker pool, and returns the
+// aggregated per-target results keyed by target.
+func (s *Scanner) Run(ctx context.Context) map[string]scanOutcome {
+	results := make(map[string]scanOutcome, len(s.targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.workers)
+
+	for _, target := range s.targets {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			results[target] = scanOutcome{Error: ctx.Err().Error()}
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			cfg := s.cfgTemplate
+			cfg.target = target
+
+			checker := NewTLSChecker(cfg)
+			checker.limiter = s.limiter
+
+			outcome := scanOutcome{}
+			if err := checker.Run(ctx); err != nil {
+				outcome.Error = err.Error()
+			} else {
+				report := buildJSONReport(checker)
+				outcome.Report = &report
+			}
+
+			mu.Lock()
+			results[target] = outcome
+			mu.Unlock()
+		}(target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// readTargets loads newline-delimited "host:port" entries from path,
+// skipping blank lines.
+func readTargets(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening targets file: %w", err)
+	}
+	defer f.Close()
+
+	var targets []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		targets = append(targets, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading targets file: %w", err)
+	}
+
+	return targets, nil
+}
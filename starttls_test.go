@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMysqlServerCapabilities(t *testing.T) {
+	// Builds a protocol-version-10 handshake payload with the given server
+	// version string and 8-byte nonce, then a filler byte, then capLower and
+	// capUpper as the two capability-flag words straddling the charset and
+	// status flags.
+	buildPayload := func(serverVersion string, nonce [8]byte, capLower, capUpper uint16) []byte {
+		var buf bytes.Buffer
+		buf.WriteByte(10)
+		buf.WriteString(serverVersion)
+		buf.WriteByte(0) // NUL terminator
+		buf.Write([]byte{1, 0, 0, 0})
+		buf.Write(nonce[:])
+		buf.WriteByte(0) // filler
+		buf.WriteByte(byte(capLower))
+		buf.WriteByte(byte(capLower >> 8))
+		buf.WriteByte(0x2d) // charset
+		buf.Write([]byte{0, 0})
+		buf.WriteByte(byte(capUpper))
+		buf.WriteByte(byte(capUpper >> 8))
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		want    uint32
+		wantErr bool
+	}{
+		{
+			name:    "capabilities split across lower and upper words",
+			payload: buildPayload("8.0.34", [8]byte{1, 2, 3, 4, 5, 6, 7, 8}, 0x0800, 0x0002),
+			want:    0x00020800,
+		},
+		{
+			name: "nonce with an embedded zero byte is not mistaken for a terminator",
+			// A NUL-scanning parser would stop at nonce[3] here instead of
+			// skipping the full fixed 8-byte field, desyncing every field
+			// that follows.
+			payload: buildPayload("8.0.34", [8]byte{1, 2, 3, 0, 5, 6, 7, 8}, 0x0800, 0x0002),
+			want:    0x00020800,
+		},
+		{
+			name:    "unsupported protocol version",
+			payload: []byte{9, 0},
+			wantErr: true,
+		},
+		{
+			name:    "empty payload",
+			payload: nil,
+			wantErr: true,
+		},
+		{
+			name:    "truncated after nonce and filler",
+			payload: append([]byte{10, 'x', 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 0}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mysqlServerCapabilities(tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("mysqlServerCapabilities() = %#x, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mysqlServerCapabilities() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("mysqlServerCapabilities() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLdapStartTLSRequest(t *testing.T) {
+	got := ldapStartTLSRequest()
+
+	want := []byte{
+		0x30, 0x1d, // LDAPMessage SEQUENCE
+		0x02, 0x01, 0x01, // messageID INTEGER 1
+		0x77, 0x18, // [APPLICATION 23] ExtendedRequest
+		0x80, 0x16, // [0] requestName
+	}
+	want = append(want, []byte(ldapStartTLSOID)...)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("ldapStartTLSRequest() = %#v, want %#v", got, want)
+	}
+}
+
+func TestContainsBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		haystack []byte
+		needle   []byte
+		want     bool
+	}{
+		{
+			name:     "found in the middle",
+			haystack: []byte{0x01, 0x0a, 0x01, 0x00, 0x02},
+			needle:   []byte{0x0a, 0x01, 0x00},
+			want:     true,
+		},
+		{
+			name:     "not present",
+			haystack: []byte{0x01, 0x0a, 0x01, 0x01, 0x02},
+			needle:   []byte{0x0a, 0x01, 0x00},
+			want:     false,
+		},
+		{
+			name:     "needle longer than haystack",
+			haystack: []byte{0x0a, 0x01},
+			needle:   []byte{0x0a, 0x01, 0x00},
+			want:     false,
+		},
+		{
+			name:     "empty needle",
+			haystack: []byte{0x0a, 0x01, 0x00},
+			needle:   nil,
+			want:     false,
+		},
+		{
+			name:     "match at the very end",
+			haystack: []byte{0x01, 0x02, 0x0a, 0x01, 0x00},
+			needle:   []byte{0x0a, 0x01, 0x00},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsBytes(tt.haystack, tt.needle); got != tt.want {
+				t.Errorf("containsBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -16,28 +17,52 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	target  string
-	timeout time.Duration
-	verbose bool
+	target   string
+	timeout  time.Duration
+	verbose  bool
+	format   string
+	starttls string
 }
 
 // TLSChecker handles TLS connection analysis
 type TLSChecker struct {
-	config     Config
-	mu         sync.RWMutex
-	results    map[uint16]bool
-	errCount   int
-	tlsVersion uint16
+	config           Config
+	mu               sync.RWMutex
+	results          map[uint16]map[uint16]bool // version -> suite ID -> supported
+	errCount         int
+	tlsVersion       uint16
+	handshakeLatency time.Duration
+	certReport       *CertReport
+	groupResults     map[tls.CurveID]bool
+	limiter          *rateLimiter // set by Scanner for batch scans; nil otherwise
 }
 
 // NewTLSChecker creates a new instance of TLSChecker
 func NewTLSChecker(cfg Config) *TLSChecker {
 	return &TLSChecker{
-		config:  cfg,
-		results: make(map[uint16]bool),
+		config:       cfg,
+		results:      make(map[uint16]map[uint16]bool),
+		groupResults: make(map[tls.CurveID]bool),
 	}
 }
 
+// scannedVersions lists every TLS version the matrix scan probes, oldest first.
+var scannedVersions = []uint16{
+	tls.VersionTLS10,
+	tls.VersionTLS11,
+	tls.VersionTLS12,
+	tls.VersionTLS13,
+}
+
+// tls13CipherSuites are the fixed TLS 1.3 suites. Go's tls.Config.CipherSuites
+// cannot select among them, so they must be probed by pinning MinVersion and
+// MaxVersion to VersionTLS13 and reading back the negotiated suite.
+var tls13CipherSuites = []*tls.CipherSuite{
+	{ID: tls.TLS_AES_128_GCM_SHA256, Name: "TLS_AES_128_GCM_SHA256"},
+	{ID: tls.TLS_AES_256_GCM_SHA384, Name: "TLS_AES_256_GCM_SHA384"},
+	{ID: tls.TLS_CHACHA20_POLY1305_SHA256, Name: "TLS_CHACHA20_POLY1305_SHA256"},
+}
+
 // Run executes the TLS checking process
 func (tc *TLSChecker) Run(ctx context.Context) error {
 	// Initial connection to get TLS version
@@ -45,11 +70,65 @@ func (tc *TLSChecker) Run(ctx context.Context) error {
 		return fmt.Errorf("initial TLS check failed: %w", err)
 	}
 
-	// Test cipher suites concurrently
-	return tc.testCipherSuites(ctx)
+	// Build the full version/cipher-suite support matrix
+	if err := tc.scanVersionMatrix(ctx); err != nil {
+		return err
+	}
+
+	// Probe supported ECDHE curves / hybrid key-exchange groups
+	return tc.scanNamedGroups(ctx)
+}
+
+// scanNamedGroups probes each entry in namedGroups by dialing with
+// CurvePreferences pinned to that one group, the natural companion to the
+// per-suite loop in testCipherSuites.
+func (tc *TLSChecker) scanNamedGroups(ctx context.Context) error {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 10) // Limit concurrent connections
+
+	for _, group := range namedGroups {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(group namedGroup) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			cfg := &tls.Config{
+				InsecureSkipVerify: true,
+				CurvePreferences:   []tls.CurveID{group.ID},
+			}
+
+			conn, err := tc.connect(ctx, cfg)
+			if err != nil {
+				if tc.config.verbose {
+					fmt.Printf("Failed testing group %s: %v\n", group.Name, err)
+				}
+				tc.recordGroupResult(group.ID, false)
+				return
+			}
+			defer conn.Close()
+
+			tc.recordGroupResult(group.ID, true)
+		}(group)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (tc *TLSChecker) recordGroupResult(group tls.CurveID, supported bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.groupResults[group] = supported
 }
 
 func (tc *TLSChecker) checkTLSVersion(ctx context.Context) error {
+	start := time.Now()
 	conn, err := tc.connect(ctx, &tls.Config{
 		InsecureSkipVerify: true,
 	})
@@ -58,11 +137,25 @@ func (tc *TLSChecker) checkTLSVersion(ctx context.Context) error {
 	}
 	defer conn.Close()
 
-	tc.tlsVersion = conn.ConnectionState().Version
+	tc.handshakeLatency = time.Since(start)
+	state := conn.ConnectionState()
+	tc.tlsVersion = state.Version
+
+	inspector := NewCertInspector(tc.config)
+	tc.certReport = inspector.Inspect(ctx, state, tc.connect)
+
 	return nil
 }
 
 func (tc *TLSChecker) connect(ctx context.Context, cfg *tls.Config) (*tls.Conn, error) {
+	if err := tc.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if tc.config.starttls != "" {
+		return tc.connectStarttls(ctx, cfg)
+	}
+
 	dialer := &net.Dialer{
 		Timeout:   tc.config.timeout,
 		KeepAlive: tc.config.timeout,
@@ -80,11 +173,101 @@ func (tc *TLSChecker) connect(ctx context.Context, cfg *tls.Config) (*tls.Conn,
 	return conn, nil
 }
 
-func (tc *TLSChecker) testCipherSuites(ctx context.Context) error {
+// connectStarttls opens a plaintext TCP connection, performs the
+// protocol-specific STARTTLS upgrade dance, and only then hands the
+// connection to tls.Client for the TLS handshake.
+func (tc *TLSChecker) connectStarttls(ctx context.Context, cfg *tls.Config) (*tls.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   tc.config.timeout,
+		KeepAlive: tc.config.timeout,
+	}
+
+	raw, err := dialer.DialContext(ctx, "tcp", tc.config.target)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("connection timeout: %w", err)
+		}
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+
+	if err := upgradeConn(raw, tc.config.starttls, tc.config.timeout); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("starttls upgrade failed: %w", err)
+	}
+
+	raw.SetDeadline(time.Time{})
+
+	// Mirror tls.DialWithDialer, which bounds the handshake itself with
+	// dialer.Timeout rather than leaving it to the bare ctx: a server that
+	// accepts STARTTLS but never completes the handshake would otherwise
+	// hang the scan forever.
+	handshakeCtx := ctx
+	if tc.config.timeout > 0 {
+		var cancel context.CancelFunc
+		handshakeCtx, cancel = context.WithTimeout(ctx, tc.config.timeout)
+		defer cancel()
+	}
+
+	tlsConn := tls.Client(raw, cfg)
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("tls handshake failed: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// scanVersionMatrix probes every version in scannedVersions and, for each,
+// the cipher suites that version can negotiate, building the full
+// version->suite support matrix.
+func (tc *TLSChecker) scanVersionMatrix(ctx context.Context) error {
+	for _, version := range scannedVersions {
+		if err := tc.testCipherSuites(ctx, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// candidateSuites returns the cipher suites worth probing for the given
+// version. TLS 1.0-1.2 share the regular stdlib suite + insecure suite
+// lists, filtered down to the ones that list the version as supported.
+// TLS 1.3's suites are fixed and not selectable via tls.Config.CipherSuites.
+func candidateSuites(version uint16) []*tls.CipherSuite {
+	if version == tls.VersionTLS13 {
+		return tls13CipherSuites
+	}
+
+	all := append(append([]*tls.CipherSuite{}, tls.CipherSuites()...), tls.InsecureCipherSuites()...)
+	var candidates []*tls.CipherSuite
+	for _, suite := range all {
+		for _, v := range suite.SupportedVersions {
+			if v == version {
+				candidates = append(candidates, suite)
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+func (tc *TLSChecker) testCipherSuites(ctx context.Context, version uint16) error {
+	suites := candidateSuites(version)
+	if len(suites) == 0 {
+		return nil
+	}
+
+	// TLS 1.3 cipher selection isn't configurable: probe once and record
+	// whichever suite the server actually negotiated.
+	if version == tls.VersionTLS13 {
+		return tc.testTLS13Suites(ctx, suites)
+	}
+
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, 10) // Limit concurrent connections
 
-	for _, suite := range tls.CipherSuites() {
+	for _, suite := range suites {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -99,21 +282,21 @@ func (tc *TLSChecker) testCipherSuites(ctx context.Context) error {
 			cfg := &tls.Config{
 				InsecureSkipVerify: true,
 				CipherSuites:       []uint16{suite.ID},
-				MinVersion:         tc.tlsVersion,
-				MaxVersion:         tc.tlsVersion,
+				MinVersion:         version,
+				MaxVersion:         version,
 			}
 
 			conn, err := tc.connect(ctx, cfg)
 			if err != nil {
 				if tc.config.verbose {
-					fmt.Printf("Failed testing %s: %v\n", suite.Name, err)
+					fmt.Printf("Failed testing %s (%s): %v\n", suite.Name, getTLSVersionString(version), err)
 				}
-				tc.recordResult(suite.ID, false)
+				tc.recordResult(version, suite.ID, false)
 				return
 			}
 			defer conn.Close()
 
-			tc.recordResult(suite.ID, true)
+			tc.recordResult(version, suite.ID, true)
 		}(suite)
 	}
 
@@ -121,31 +304,42 @@ func (tc *TLSChecker) testCipherSuites(ctx context.Context) error {
 	return nil
 }
 
-func (tc *TLSChecker) recordResult(suiteID uint16, supported bool) {
-	tc.mu.Lock()
-	defer tc.mu.Unlock()
-	tc.results[suiteID] = supported
-	if !supported {
-		tc.errCount++
+func (tc *TLSChecker) testTLS13Suites(ctx context.Context, suites []*tls.CipherSuite) error {
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+		MaxVersion:         tls.VersionTLS13,
 	}
-}
 
-func (tc *TLSChecker) printResults() {
-	fmt.Printf("\nTLS Connection Information for %s:\n", tc.config.target)
-	fmt.Printf("TLS Version: %s\n\n", getTLSVersionString(tc.tlsVersion))
-
-	fmt.Println("Supported Cipher Suites:")
-	for _, suite := range tls.CipherSuites() {
-		supported := tc.results[suite.ID]
-		if supported {
-			fmt.Printf("✓ %s\n", suite.Name)
-		} else if tc.config.verbose {
-			fmt.Printf("✗ %s\n", suite.Name)
+	conn, err := tc.connect(ctx, cfg)
+	if err != nil {
+		if tc.config.verbose {
+			fmt.Printf("Failed testing TLS 1.3 suites: %v\n", err)
 		}
+		for _, suite := range suites {
+			tc.recordResult(tls.VersionTLS13, suite.ID, false)
+		}
+		return nil
 	}
+	defer conn.Close()
 
-	fmt.Printf("\nSummary: %d supported, %d unsupported cipher suites\n",
-		len(tc.results)-tc.errCount, tc.errCount)
+	negotiated := conn.ConnectionState().CipherSuite
+	for _, suite := range suites {
+		tc.recordResult(tls.VersionTLS13, suite.ID, suite.ID == negotiated)
+	}
+	return nil
+}
+
+func (tc *TLSChecker) recordResult(version, suiteID uint16, supported bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.results[version] == nil {
+		tc.results[version] = make(map[uint16]bool)
+	}
+	tc.results[version][suiteID] = supported
+	if !supported {
+		tc.errCount++
+	}
 }
 
 func getTLSVersionString(version uint16) string {
@@ -163,16 +357,29 @@ func getTLSVersionString(version uint16) string {
 
 func main() {
 	cfg := Config{}
+	var targetsFile string
+	var workers int
+	var rps float64
 	flag.StringVar(&cfg.target, "url", "", "Target URL (e.g., example.com:443)")
 	flag.DurationVar(&cfg.timeout, "timeout", 5*time.Second, "Connection timeout")
 	flag.BoolVar(&cfg.verbose, "verbose", false, "Show detailed output including failures")
+	flag.StringVar(&cfg.format, "format", "text", "Output format: text|json|sarif")
+	flag.StringVar(&cfg.starttls, "starttls", "", "Upgrade a plaintext connection before the TLS handshake: smtp|imap|pop3|xmpp|ldap|postgres|mysql|ftp")
+	flag.StringVar(&targetsFile, "targets", "", "File of newline-delimited host:port targets for batch scanning")
+	flag.IntVar(&workers, "workers", 10, "Number of targets to scan concurrently in batch mode")
+	flag.Float64Var(&rps, "rps", 0, "Global connection rate limit in new connections/sec (0 = unlimited); batch mode only")
 	flag.Parse()
 
-	if cfg.target == "" {
+	if cfg.target == "" && targetsFile == "" {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if cfg.starttls != "" && !validStarttlsProtocol(cfg.starttls) {
+		fmt.Fprintf(os.Stderr, "Error: unsupported -starttls protocol %q\n", cfg.starttls)
+		os.Exit(1)
+	}
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -185,11 +392,49 @@ func main() {
 		cancel()
 	}()
 
+	if targetsFile != "" {
+		runBatch(ctx, targetsFile, cfg, workers, rps)
+		return
+	}
+
+	reporter, err := reporterFor(cfg.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	checker := NewTLSChecker(cfg)
 	if err := checker.Run(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	checker.printResults()
+	if err := reporter.Report(os.Stdout, checker); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runBatch scans every target listed in targetsFile and writes the
+// aggregated results as a single JSON document to stdout.
+func runBatch(ctx context.Context, targetsFile string, cfgTemplate Config, workers int, rps float64) {
+	targets, err := readTargets(targetsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: targets file contains no targets")
+		os.Exit(1)
+	}
+
+	scanner := NewScanner(targets, cfgTemplate, workers, rps)
+	results := scanner.Run(ctx)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write batch report: %v\n", err)
+		os.Exit(1)
+	}
 }